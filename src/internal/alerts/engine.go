@@ -0,0 +1,229 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/portfolio-insights/market-service/internal/lifecycle"
+	"github.com/portfolio-insights/market-service/internal/providers"
+)
+
+const (
+	defaultPollInterval   = 10 * time.Second
+	defaultWorkerPool     = 8
+	webhookMaxAttempts    = 4
+	webhookBackoffBase    = 500 * time.Millisecond
+	webhookRequestTimeout = 5 * time.Second
+)
+
+// Engine periodically evaluates active alerts against live prices and
+// delivers webhooks for the ones that cross their threshold.
+type Engine struct {
+	store    AlertStore
+	provider providers.Provider
+
+	pollInterval  time.Duration
+	workerPool    int
+	webhookSecret string
+
+	client *http.Client
+
+	// conns registers each in-flight webhook delivery goroutine so
+	// graceful shutdown can wait for (or accurately report) it, the same
+	// way it tracks /stream clients and the Engine's own Run loop.
+	conns *lifecycle.Group
+}
+
+// NewEngine builds an Engine. pollInterval and workerPool fall back to
+// sensible defaults when zero. conns is used to track in-flight webhook
+// delivery goroutines for graceful shutdown.
+func NewEngine(store AlertStore, provider providers.Provider, pollInterval time.Duration, workerPool int, webhookSecret string, conns *lifecycle.Group) *Engine {
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	if workerPool <= 0 {
+		workerPool = defaultWorkerPool
+	}
+	return &Engine{
+		store:         store,
+		provider:      provider,
+		pollInterval:  pollInterval,
+		workerPool:    workerPool,
+		webhookSecret: webhookSecret,
+		client:        &http.Client{Timeout: webhookRequestTimeout, Transport: NewWebhookTransport()},
+		conns:         conns,
+	}
+}
+
+// Run evaluates alerts on every poll tick until ctx is cancelled.
+func (e *Engine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.tick(ctx)
+		}
+	}
+}
+
+// tick evaluates every active alert, grouped and fetched once per ticker so
+// a ticker watched by many alerts costs one provider call per tick.
+func (e *Engine) tick(ctx context.Context) {
+	active, err := e.store.ListActive()
+	if err != nil {
+		log.Printf("⚠️  alerts: failed to list active alerts: %v", err)
+		return
+	}
+	if len(active) == 0 {
+		return
+	}
+
+	byTicker := make(map[string][]Alert)
+	for _, a := range active {
+		byTicker[a.Ticker] = append(byTicker[a.Ticker], a)
+	}
+
+	sem := make(chan struct{}, e.workerPool)
+	var wg sync.WaitGroup
+	for ticker, group := range byTicker {
+		ticker, group := ticker, group
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			e.evaluateTicker(ctx, ticker, group)
+		}()
+	}
+	wg.Wait()
+}
+
+func (e *Engine) evaluateTicker(ctx context.Context, ticker string, group []Alert) {
+	last, err := e.provider.LatestPrice(ctx, ticker)
+	if err != nil {
+		log.Printf("⚠️  alerts: price lookup failed for %s: %v", ticker, err)
+		return
+	}
+	current := last.PrevClose
+	if last.Last != nil {
+		current = *last.Last
+	}
+
+	for _, a := range group {
+		crossed := (a.Direction == "above" && current > a.Price) || (a.Direction == "below" && current < a.Price)
+		if !crossed {
+			continue
+		}
+		e.fire(ctx, a, current)
+	}
+}
+
+// fire marks the alert triggered and delivers its webhook with retries.
+// Triggering is recorded before delivery so a crash mid-delivery can't
+// re-fire the same alert forever.
+func (e *Engine) fire(ctx context.Context, a Alert, currentPrice float64) {
+	now := time.Now().UTC()
+	if err := e.store.MarkTriggered(a.ID, now); err != nil {
+		log.Printf("⚠️  alerts: failed to mark %s triggered: %v", a.ID, err)
+		return
+	}
+	if e.conns != nil {
+		e.conns.Add(1)
+	}
+	go func() {
+		if e.conns != nil {
+			defer e.conns.Done()
+		}
+		e.deliver(ctx, a, currentPrice, now)
+	}()
+}
+
+type webhookPayload struct {
+	AlertID     string    `json:"alertId"`
+	Ticker      string    `json:"ticker"`
+	Price       float64   `json:"price"`
+	Direction   string    `json:"direction"`
+	Current     float64   `json:"currentPrice"`
+	TriggeredAt time.Time `json:"triggeredAt"`
+}
+
+// deliver POSTs the signed payload to a.CallbackURL, retrying with
+// exponential backoff. Repeated failure is recorded in the dead-letter log.
+func (e *Engine) deliver(ctx context.Context, a Alert, currentPrice float64, triggeredAt time.Time) {
+	payload, err := json.Marshal(webhookPayload{
+		AlertID:     a.ID,
+		Ticker:      a.Ticker,
+		Price:       a.Price,
+		Direction:   a.Direction,
+		Current:     currentPrice,
+		TriggeredAt: triggeredAt,
+	})
+	if err != nil {
+		log.Printf("⚠️  alerts: failed to encode webhook payload for %s: %v", a.ID, err)
+		return
+	}
+	signature := e.sign(payload)
+
+	var lastErr error
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.CallbackURL, bytes.NewReader(payload))
+		if err != nil {
+			lastErr = err
+			break
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Signature", signature)
+
+		resp, err := e.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return
+			}
+			lastErr = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		} else {
+			lastErr = err
+		}
+
+		if attempt < webhookMaxAttempts {
+			backoff := time.NewTimer(webhookBackoffBase * time.Duration(1<<uint(attempt-1)))
+			select {
+			case <-backoff.C:
+			case <-ctx.Done():
+				backoff.Stop()
+				e.deadLetter(a, ctx.Err())
+				return
+			}
+		}
+	}
+
+	e.deadLetter(a, lastErr)
+}
+
+// deadLetter records a webhook that failed every delivery attempt. This is
+// a plain log line today; an operator grepping for "ALERT DEAD-LETTER" can
+// find alerts that need manual follow-up.
+func (e *Engine) deadLetter(a Alert, err error) {
+	log.Printf("💀 ALERT DEAD-LETTER: alert=%s ticker=%s callbackURL=%s error=%v", a.ID, a.Ticker, a.CallbackURL, err)
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of payload using the engine's
+// webhook secret, so receivers can verify the request came from us.
+func (e *Engine) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(e.webhookSecret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,207 @@
+package alerts
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/portfolio-insights/market-service/internal/providers"
+)
+
+// memStore is a minimal in-memory AlertStore for tests.
+type memStore struct {
+	mu     sync.Mutex
+	alerts map[string]Alert
+}
+
+func newMemStore(alerts ...Alert) *memStore {
+	s := &memStore{alerts: make(map[string]Alert)}
+	for _, a := range alerts {
+		s.alerts[a.ID] = a
+	}
+	return s
+}
+
+func (s *memStore) Create(a Alert) (Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts[a.ID] = a
+	return a, nil
+}
+
+func (s *memStore) List() ([]Alert, error) {
+	return s.ListActive()
+}
+
+func (s *memStore) ListActive() ([]Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Alert
+	for _, a := range s.alerts {
+		if a.TriggeredAt == nil {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.alerts, id)
+	return nil
+}
+
+func (s *memStore) MarkTriggered(id string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.alerts[id]
+	if !ok {
+		return ErrNotFound
+	}
+	a.TriggeredAt = &t
+	s.alerts[id] = a
+	return nil
+}
+
+// fixedPriceProvider reports the same last price for every ticker.
+type fixedPriceProvider struct {
+	price float64
+}
+
+func (p *fixedPriceProvider) HistoricalPrices(ctx context.Context, ticker, start, freq string) ([]providers.PricePoint, error) {
+	return nil, nil
+}
+
+func (p *fixedPriceProvider) LatestPrice(ctx context.Context, ticker string) (providers.LastPrice, error) {
+	last := p.price
+	return providers.LastPrice{Last: &last}, nil
+}
+
+func (p *fixedPriceProvider) HealthCheck(ctx context.Context) error { return nil }
+
+// newTestEngine builds an Engine for tests and swaps in a plain
+// http.Client in place of NewWebhookTransport's dial-time SSRF guard,
+// since tests deliberately deliver to httptest's loopback servers (which
+// that guard is specifically designed to reject in production).
+func newTestEngine(store AlertStore, provider providers.Provider, webhookSecret string) *Engine {
+	e := NewEngine(store, provider, time.Minute, 2, webhookSecret, nil)
+	e.client = &http.Client{Timeout: webhookRequestTimeout}
+	return e
+}
+
+func TestEngineFiresWebhookOnThresholdCrossing(t *testing.T) {
+	received := make(chan webhookPayload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		received <- p
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	store := newMemStore(Alert{ID: "a1", Ticker: "AAPL", Price: 100, Direction: "above", CallbackURL: srv.URL})
+	e := newTestEngine(store, &fixedPriceProvider{price: 150}, "secret")
+
+	e.tick(context.Background())
+
+	select {
+	case p := <-received:
+		if p.AlertID != "a1" || p.Current != 150 {
+			t.Fatalf("unexpected payload: %+v", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for webhook delivery")
+	}
+
+	active, _ := store.ListActive()
+	if len(active) != 0 {
+		t.Fatalf("expected alert to be marked triggered and excluded from ListActive, got %d active", len(active))
+	}
+}
+
+func TestEngineDoesNotFireWhenNotCrossed(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	store := newMemStore(Alert{ID: "a1", Ticker: "AAPL", Price: 200, Direction: "above", CallbackURL: srv.URL})
+	e := newTestEngine(store, &fixedPriceProvider{price: 150}, "secret")
+
+	e.tick(context.Background())
+	// Give any wrongly-fired delivery goroutine a moment to reach the server.
+	time.Sleep(50 * time.Millisecond)
+
+	if called {
+		t.Fatal("webhook should not fire when the alert's threshold hasn't been crossed")
+	}
+}
+
+func TestEngineSignsWebhookPayload(t *testing.T) {
+	const secret = "super-secret"
+	var gotSig string
+	var gotBody []byte
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-Signature")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		close(done)
+	}))
+	defer srv.Close()
+
+	store := newMemStore(Alert{ID: "a1", Ticker: "AAPL", Price: 100, Direction: "above", CallbackURL: srv.URL})
+	e := newTestEngine(store, &fixedPriceProvider{price: 150}, secret)
+
+	e.tick(context.Background())
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for webhook delivery")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(gotBody)
+	want := hex.EncodeToString(mac.Sum(nil))
+	if gotSig != want {
+		t.Fatalf("signature mismatch: got %s, want %s", gotSig, want)
+	}
+}
+
+func TestDeliverAbandonsRetriesWhenContextCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	e := newTestEngine(newMemStore(), &fixedPriceProvider{}, "secret")
+	a := Alert{ID: "a1", Ticker: "AAPL", Price: 100, Direction: "above", CallbackURL: srv.URL}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	e.deliver(ctx, a, 150, time.Now())
+	elapsed := time.Since(start)
+
+	// The shortest backoff (webhookBackoffBase) is 500ms; an engine that
+	// respects ctx should abandon the retry loop well before then instead
+	// of running out all webhookMaxAttempts attempts with full backoffs.
+	if elapsed > 400*time.Millisecond {
+		t.Fatalf("deliver took %v to return after ctx was cancelled, expected it to abandon retries promptly", elapsed)
+	}
+}
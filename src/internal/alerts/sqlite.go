@@ -0,0 +1,127 @@
+package alerts
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3" // SQLite driver, registered via side effect
+)
+
+// SQLiteStore is the default AlertStore backend. It's intentionally a thin
+// wrapper around database/sql rather than an ORM, matching the rest of the
+// service's preference for the standard library.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating if necessary) the SQLite database at path
+// and ensures the alerts table exists.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("alerts: open sqlite: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("alerts: ping sqlite: %w", err)
+	}
+
+	const schema = `
+	CREATE TABLE IF NOT EXISTS alerts (
+		id           TEXT PRIMARY KEY,
+		ticker       TEXT NOT NULL,
+		price        REAL NOT NULL,
+		direction    TEXT NOT NULL,
+		callback_url TEXT NOT NULL,
+		created_at   TIMESTAMP NOT NULL,
+		triggered_at TIMESTAMP
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("alerts: create schema: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Create(alert Alert) (Alert, error) {
+	alert.ID = uuid.NewString()
+	alert.CreatedAt = time.Now().UTC()
+
+	_, err := s.db.Exec(
+		`INSERT INTO alerts (id, ticker, price, direction, callback_url, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		alert.ID, alert.Ticker, alert.Price, alert.Direction, alert.CallbackURL, alert.CreatedAt,
+	)
+	if err != nil {
+		return Alert{}, fmt.Errorf("alerts: insert: %w", err)
+	}
+	return alert, nil
+}
+
+func (s *SQLiteStore) List() ([]Alert, error) {
+	rows, err := s.db.Query(
+		`SELECT id, ticker, price, direction, callback_url, created_at, triggered_at FROM alerts ORDER BY created_at DESC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("alerts: list: %w", err)
+	}
+	defer rows.Close()
+	return scanAlerts(rows)
+}
+
+func (s *SQLiteStore) ListActive() ([]Alert, error) {
+	rows, err := s.db.Query(
+		`SELECT id, ticker, price, direction, callback_url, created_at, triggered_at FROM alerts WHERE triggered_at IS NULL`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("alerts: list active: %w", err)
+	}
+	defer rows.Close()
+	return scanAlerts(rows)
+}
+
+func (s *SQLiteStore) Delete(id string) error {
+	res, err := s.db.Exec(`DELETE FROM alerts WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("alerts: delete: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("alerts: delete: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) MarkTriggered(id string, t time.Time) error {
+	res, err := s.db.Exec(`UPDATE alerts SET triggered_at = ? WHERE id = ?`, t, id)
+	if err != nil {
+		return fmt.Errorf("alerts: mark triggered: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("alerts: mark triggered: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanAlerts(rows *sql.Rows) ([]Alert, error) {
+	var out []Alert
+	for rows.Next() {
+		var a Alert
+		var triggeredAt sql.NullTime
+		if err := rows.Scan(&a.ID, &a.Ticker, &a.Price, &a.Direction, &a.CallbackURL, &a.CreatedAt, &triggeredAt); err != nil {
+			return nil, fmt.Errorf("alerts: scan: %w", err)
+		}
+		if triggeredAt.Valid {
+			a.TriggeredAt = &triggeredAt.Time
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
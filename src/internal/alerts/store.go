@@ -0,0 +1,39 @@
+// Package alerts implements a persistent price-alert subsystem: a store for
+// alert records and a background Engine that evaluates them against live
+// prices and delivers webhooks when they trigger.
+package alerts
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by AlertStore methods when no alert matches the
+// given id.
+var ErrNotFound = errors.New("alerts: not found")
+
+// Alert is a persisted price alert. TriggeredAt is nil until the alert
+// fires, at which point the Engine stops evaluating it.
+type Alert struct {
+	ID          string     `json:"id"`
+	Ticker      string     `json:"ticker"`
+	Price       float64    `json:"price"`
+	Direction   string     `json:"direction"` // "above" or "below"
+	CallbackURL string     `json:"callbackURL"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	TriggeredAt *time.Time `json:"triggeredAt,omitempty"`
+}
+
+// AlertStore persists alert records. SQLiteStore is the only implementation
+// today; a Postgres-backed one can satisfy the same interface later.
+type AlertStore interface {
+	Create(alert Alert) (Alert, error)
+	List() ([]Alert, error)
+	// ListActive returns alerts that have not yet triggered, for the Engine
+	// to evaluate on each poll tick.
+	ListActive() ([]Alert, error)
+	Delete(id string) error
+	// MarkTriggered records that an alert fired at t, so it's excluded from
+	// future evaluation.
+	MarkTriggered(id string, t time.Time) error
+}
@@ -0,0 +1,81 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrUnsafeCallbackURL is returned by ValidateCallbackURL when a callback
+// URL is rejected.
+var ErrUnsafeCallbackURL = errors.New("alerts: callback URL not allowed")
+
+// ValidateCallbackURL rejects callback URLs that aren't plain http(s), or
+// that resolve to a loopback/private/link-local address. Without this, a
+// caller could register an alert whose webhook points at internal
+// infrastructure (e.g. a cloud metadata endpoint) and have the Engine
+// dutifully retry signed requests against it.
+func ValidateCallbackURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsafeCallbackURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q is not http/https", ErrUnsafeCallbackURL, u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrUnsafeCallbackURL)
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return fmt.Errorf("%w: could not resolve %q: %v", ErrUnsafeCallbackURL, host, err)
+	}
+	for _, ip := range ips {
+		if isDisallowedCallbackIP(ip) {
+			return fmt.Errorf("%w: %q resolves to disallowed address %s", ErrUnsafeCallbackURL, host, ip)
+		}
+	}
+	return nil
+}
+
+// isDisallowedCallbackIP flags loopback, private, link-local and
+// unspecified addresses, covering things like 127.0.0.1, 169.254.169.254
+// (cloud metadata endpoints) and RFC1918 internal ranges.
+func isDisallowedCallbackIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// NewWebhookTransport returns an http.RoundTripper for delivering alert
+// webhooks whose dialer re-checks the IP it's actually about to connect
+// to, immediately before the connect syscall. ValidateCallbackURL alone
+// only checks DNS at alert-creation time; since delivery re-resolves the
+// host at poll time, a callback host that resolves publicly when the
+// alert is created could be rebound to a disallowed address (e.g.
+// 169.254.169.254) before the webhook is ever sent. Pinning the check to
+// the address actually dialed closes that window.
+func NewWebhookTransport() http.RoundTripper {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrUnsafeCallbackURL, err)
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("%w: could not parse dial address %q", ErrUnsafeCallbackURL, host)
+			}
+			if isDisallowedCallbackIP(ip) {
+				return fmt.Errorf("%w: refusing to connect to %s", ErrUnsafeCallbackURL, ip)
+			}
+			return nil
+		},
+	}
+	return &http.Transport{DialContext: dialer.DialContext}
+}
@@ -0,0 +1,45 @@
+package alerts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateCallbackURLRejectsPrivateAddresses(t *testing.T) {
+	cases := []string{
+		"http://127.0.0.1:8080/webhook",
+		"http://169.254.169.254/latest/meta-data",
+		"ftp://example.com/webhook",
+		"not-a-url",
+	}
+	for _, raw := range cases {
+		if err := ValidateCallbackURL(raw); err == nil {
+			t.Errorf("ValidateCallbackURL(%q) = nil, want an error", raw)
+		}
+	}
+}
+
+// TestWebhookTransportRejectsLoopbackAtDialTime guards against the
+// TOCTOU window ValidateCallbackURL alone can't close: a host that
+// resolves publicly at alert-creation time but is rebound to a loopback
+// or private address before delivery. NewWebhookTransport's dialer must
+// re-check the address it's actually about to connect to.
+func TestWebhookTransportRejectsLoopbackAtDialTime(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewWebhookTransport()}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	_, err = client.Do(req)
+	if err == nil {
+		t.Fatal("expected dial to the loopback test server to be rejected, got nil error")
+	}
+}
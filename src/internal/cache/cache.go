@@ -0,0 +1,16 @@
+// Package cache provides a small TTL key/value cache used to sit in front
+// of upstream market-data calls and stay within Tiingo's rate limits.
+package cache
+
+import "time"
+
+// Cache is implemented by every backend (in-memory, Redis, ...). Handlers
+// depend only on this interface so the backend can be swapped via env var.
+type Cache interface {
+	// Get returns the cached value for key and whether it was found
+	// (and not expired).
+	Get(key string) ([]byte, bool)
+	// Set stores val under key for ttl. A zero or negative ttl means the
+	// entry never expires on its own.
+	Set(key string, val []byte, ttl time.Duration)
+}
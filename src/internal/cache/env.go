@@ -0,0 +1,20 @@
+package cache
+
+import "os"
+
+// FromEnv builds a Cache based on the CACHE_BACKEND env var:
+//
+//	memory  - InMemoryCache (default)
+//	redis   - RedisCache, connecting to REDIS_ADDR (default "localhost:6379")
+func FromEnv() Cache {
+	switch os.Getenv("CACHE_BACKEND") {
+	case "redis":
+		addr := os.Getenv("REDIS_ADDR")
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		return NewRedisCache(addr)
+	default:
+		return NewInMemoryCache()
+	}
+}
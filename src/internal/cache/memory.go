@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	numShards            = 16
+	defaultMaxPerShard   = 2048
+	defaultJanitorPeriod = 30 * time.Second
+)
+
+type entry struct {
+	key       string
+	val       []byte
+	expiresAt time.Time
+	elem      *list.Element
+}
+
+// shard is a single lock-protected bucket of the cache, evicting its least
+// recently used entry once it grows past maxEntries.
+type shard struct {
+	mu         sync.Mutex
+	items      map[string]*entry
+	order      *list.List // front = most recently used
+	maxEntries int
+}
+
+func newShard(maxEntries int) *shard {
+	return &shard{
+		items:      make(map[string]*entry),
+		order:      list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+func (s *shard) get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		s.removeLocked(e)
+		return nil, false
+	}
+	s.order.MoveToFront(e.elem)
+	return e.val, true
+}
+
+func (s *shard) set(key string, val []byte, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if e, ok := s.items[key]; ok {
+		e.val = val
+		e.expiresAt = expiresAt
+		s.order.MoveToFront(e.elem)
+		return
+	}
+
+	e := &entry{key: key, val: val, expiresAt: expiresAt}
+	e.elem = s.order.PushFront(e)
+	s.items[key] = e
+
+	for len(s.items) > s.maxEntries {
+		oldest := s.order.Back()
+		if oldest == nil {
+			break
+		}
+		s.removeLocked(oldest.Value.(*entry))
+	}
+}
+
+func (s *shard) removeLocked(e *entry) {
+	s.order.Remove(e.elem)
+	delete(s.items, e.key)
+}
+
+// evictExpired drops every expired entry in the shard; called periodically
+// by the janitor goroutine so idle entries don't linger until the next Get.
+func (s *shard) evictExpired(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, e := range s.items {
+		if !e.expiresAt.IsZero() && now.After(e.expiresAt) {
+			s.order.Remove(e.elem)
+			delete(s.items, key)
+		}
+	}
+}
+
+// InMemoryCache is a sharded, in-process TTL cache with LRU eviction per
+// shard. It's the default Cache backend; use RedisCache when multiple
+// replicas need to share a cache.
+type InMemoryCache struct {
+	shards [numShards]*shard
+	stop   chan struct{}
+}
+
+// NewInMemoryCache builds an InMemoryCache and starts its background
+// janitor goroutine, which sweeps expired entries every 30s so memory is
+// reclaimed even for keys nobody reads again.
+func NewInMemoryCache() *InMemoryCache {
+	c := &InMemoryCache{stop: make(chan struct{})}
+	for i := range c.shards {
+		c.shards[i] = newShard(defaultMaxPerShard)
+	}
+	go c.janitor()
+	return c
+}
+
+func (c *InMemoryCache) janitor() {
+	t := time.NewTicker(defaultJanitorPeriod)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-t.C:
+			for _, s := range c.shards {
+				s.evictExpired(now)
+			}
+		}
+	}
+}
+
+// Close stops the janitor goroutine. Not required for correctness (the
+// process owns the cache for its whole lifetime today), but avoids leaking
+// the goroutine in tests that construct multiple caches.
+func (c *InMemoryCache) Close() {
+	close(c.stop)
+}
+
+func (c *InMemoryCache) Get(key string) ([]byte, bool) {
+	return c.shardFor(key).get(key)
+}
+
+func (c *InMemoryCache) Set(key string, val []byte, ttl time.Duration) {
+	c.shardFor(key).set(key, val, ttl)
+}
+
+func (c *InMemoryCache) shardFor(key string) *shard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()%numShards]
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInMemoryCacheGetSet(t *testing.T) {
+	c := NewInMemoryCache()
+	defer c.Close()
+
+	if _, ok := c.Get("missing"); ok {
+		t.Fatal("expected miss for key never set")
+	}
+
+	c.Set("AAPL", []byte("189.42"), time.Minute)
+	val, ok := c.Get("AAPL")
+	if !ok || string(val) != "189.42" {
+		t.Fatalf("got (%s, %v), want (189.42, true)", val, ok)
+	}
+}
+
+func TestInMemoryCacheExpiresByTTL(t *testing.T) {
+	c := NewInMemoryCache()
+	defer c.Close()
+
+	c.Set("AAPL", []byte("189.42"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("AAPL"); ok {
+		t.Fatal("expected expired entry to be evicted on Get")
+	}
+}
+
+func TestInMemoryCacheZeroTTLNeverExpires(t *testing.T) {
+	c := NewInMemoryCache()
+	defer c.Close()
+
+	c.Set("AAPL", []byte("189.42"), 0)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("AAPL"); !ok {
+		t.Fatal("expected zero-TTL entry to survive")
+	}
+}
+
+func TestShardEvictsLeastRecentlyUsed(t *testing.T) {
+	s := newShard(2)
+
+	s.set("a", []byte("1"), 0)
+	s.set("b", []byte("2"), 0)
+	// Touch "a" so it's most-recently-used, making "b" the eviction target.
+	s.get("a")
+	s.set("c", []byte("3"), 0)
+
+	if _, ok := s.get("b"); ok {
+		t.Fatal("expected least-recently-used entry \"b\" to be evicted")
+	}
+	if _, ok := s.get("a"); !ok {
+		t.Fatal("expected recently-used entry \"a\" to survive eviction")
+	}
+	if _, ok := s.get("c"); !ok {
+		t.Fatal("expected newly-inserted entry \"c\" to be present")
+	}
+}
+
+func TestShardEvictExpiredSweepsExpiredEntries(t *testing.T) {
+	s := newShard(defaultMaxPerShard)
+	s.set("a", []byte("1"), time.Millisecond)
+	s.set("b", []byte("2"), 0)
+
+	s.evictExpired(time.Now().Add(time.Hour))
+
+	if _, ok := s.get("a"); ok {
+		t.Fatal("expected expired entry to be swept")
+	}
+	if _, ok := s.get("b"); !ok {
+		t.Fatal("expected non-expiring entry to survive the sweep")
+	}
+}
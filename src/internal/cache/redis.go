@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is a Cache backend shared across replicas. Select it with
+// CACHE_BACKEND=redis.
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache builds a RedisCache connected to addr (host:port).
+func NewRedisCache(addr string) *RedisCache {
+	return &RedisCache{client: redis.NewClient(&redis.Options{Addr: addr})}
+}
+
+func (c *RedisCache) Get(key string) ([]byte, bool) {
+	val, err := c.client.Get(context.Background(), key).Bytes()
+	if err != nil {
+		// Covers both redis.Nil (miss) and any connection error; either way
+		// the caller should treat this as a cache miss and fall through to
+		// the upstream call.
+		return nil, false
+	}
+	return val, true
+}
+
+func (c *RedisCache) Set(key string, val []byte, ttl time.Duration) {
+	// Errors are intentionally not propagated: a failed cache write should
+	// not fail the request, it just means the next request misses too.
+	c.client.Set(context.Background(), key, val, ttl)
+}
@@ -0,0 +1,58 @@
+// Package lifecycle tracks the service's long-lived handlers (streaming
+// WebSocket clients, the alert engine loop, ...) so graceful shutdown can
+// wait for them to drain instead of killing them outright.
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Group is a sync.WaitGroup that also exposes how many registered
+// goroutines are still outstanding, so shutdown logging can report
+// drained-vs-force-closed counts.
+type Group struct {
+	wg     sync.WaitGroup
+	active int64
+}
+
+// New returns an empty Group.
+func New() *Group {
+	return &Group{}
+}
+
+// Add registers delta additional long-lived handlers.
+func (g *Group) Add(delta int) {
+	g.wg.Add(delta)
+	atomic.AddInt64(&g.active, int64(delta))
+}
+
+// Done marks one registered handler as finished.
+func (g *Group) Done() {
+	g.wg.Done()
+	atomic.AddInt64(&g.active, -1)
+}
+
+// Active returns the number of handlers registered but not yet Done.
+func (g *Group) Active() int64 {
+	return atomic.LoadInt64(&g.active)
+}
+
+// Wait blocks until every registered handler calls Done, or ctx is done,
+// whichever comes first. A non-nil error means ctx expired first and
+// Active() handlers are still outstanding.
+func (g *Group) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
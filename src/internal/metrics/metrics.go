@@ -0,0 +1,184 @@
+// Package metrics holds the service's Prometheus collectors and the HTTP
+// middleware / RoundTripper wrappers that feed them.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// HTTPRequestsTotal counts client-facing requests by route, method and
+	// status code.
+	HTTPRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total HTTP requests handled, by route, method and status code.",
+		},
+		[]string{"route", "method", "code"},
+	)
+
+	// HTTPRequestDuration measures client-facing request latency by route.
+	HTTPRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds, by route.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"route"},
+	)
+
+	// TiingoRequestsTotal counts upstream Tiingo calls by endpoint and
+	// status code, so operators can see upstream error rates separately
+	// from client-facing ones.
+	TiingoRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tiingo_requests_total",
+			Help: "Total requests made to the Tiingo API, by endpoint and status code.",
+		},
+		[]string{"endpoint", "code"},
+	)
+
+	// TiingoRequestDuration measures upstream Tiingo call latency by
+	// endpoint.
+	TiingoRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "tiingo_request_duration_seconds",
+			Help:    "Tiingo API request latency in seconds, by endpoint.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"endpoint"},
+	)
+
+	// CacheHitsTotal and CacheMissesTotal count lookups against the
+	// response cache, by route.
+	CacheHitsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_hits_total",
+			Help: "Total cache hits, by route.",
+		},
+		[]string{"route"},
+	)
+	CacheMissesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_misses_total",
+			Help: "Total cache misses, by route.",
+		},
+		[]string{"route"},
+	)
+)
+
+func init() {
+	// The default registerer already carries a Go/process collector pair
+	// (registered by client_golang itself), so only register our own
+	// collectors here to avoid a "duplicate metrics collector
+	// registration attempted" panic on import.
+	prometheus.MustRegister(
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		TiingoRequestsTotal,
+		TiingoRequestDuration,
+		CacheHitsTotal,
+		CacheMissesTotal,
+	)
+}
+
+// Handler serves the /metrics endpoint.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Instrument wraps next so every request to it is counted and timed under
+// route. route should be the registered pattern (e.g. "/stocks"), not the
+// raw request path, to keep cardinality bounded.
+func Instrument(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		HTTPRequestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+		HTTPRequestDuration.WithLabelValues(route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// statusRecorder captures the status code written by a handler so it can be
+// reported after the fact; http.ResponseWriter has no getter for it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack passes through to the wrapped writer so WebSocket upgrades (e.g.
+// /stream) still work behind Instrument.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("metrics: underlying ResponseWriter does not implement http.Hijacker")
+	}
+	return h.Hijack()
+}
+
+// tiingoTransport wraps an http.RoundTripper to record TiingoRequestsTotal /
+// TiingoRequestDuration for every call made through it.
+type tiingoTransport struct {
+	base http.RoundTripper
+}
+
+// NewTiingoTransport wraps base (or http.DefaultTransport if nil) so every
+// request made through the resulting RoundTripper is instrumented.
+func NewTiingoTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &tiingoTransport{base: base}
+}
+
+func (t *tiingoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	endpoint := classifyTiingoEndpoint(req.URL.Path)
+	TiingoRequestsTotal.WithLabelValues(endpoint, code).Inc()
+	TiingoRequestDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
+
+var (
+	tiingoDailyPricesPath = regexp.MustCompile(`^/tiingo/daily/[^/]+/prices$`)
+	tiingoIEXPath         = regexp.MustCompile(`^/iex/[^/]+$`)
+)
+
+// classifyTiingoEndpoint maps a Tiingo request path to a logical endpoint
+// name. Tiingo's paths embed the ticker symbol (e.g.
+// /tiingo/daily/AAPL/prices), so labeling with the raw path would create an
+// unbounded number of Prometheus time series, one per ticker ever queried.
+func classifyTiingoEndpoint(path string) string {
+	switch {
+	case tiingoDailyPricesPath.MatchString(path):
+		return "daily_prices"
+	case tiingoIEXPath.MatchString(path):
+		return "iex_latest"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,158 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const alpacaDataBaseURL = "https://data.alpaca.markets"
+
+// AlpacaProvider implements Provider against Alpaca's market data API.
+type AlpacaProvider struct {
+	keyID     string
+	secretKey string
+	client    *http.Client
+}
+
+// NewAlpacaProvider builds an AlpacaProvider authenticated with the given
+// API key ID and secret key.
+func NewAlpacaProvider(keyID, secretKey string) *AlpacaProvider {
+	return &AlpacaProvider{keyID: keyID, secretKey: secretKey, client: &http.Client{}}
+}
+
+type alpacaBar struct {
+	Timestamp string  `json:"t"`
+	Close     float64 `json:"c"`
+}
+
+type alpacaBarsResponse struct {
+	Bars []alpacaBar `json:"bars"`
+}
+
+type alpacaQuote struct {
+	AskPrice float64 `json:"ap"`
+	BidPrice float64 `json:"bp"`
+}
+
+type alpacaQuoteResponse struct {
+	Quote alpacaQuote `json:"quote"`
+}
+
+func (p *AlpacaProvider) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("APCA-API-KEY-ID", p.keyID)
+	req.Header.Set("APCA-API-SECRET-KEY", p.secretKey)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, classifyNetErr("alpaca", err)
+	}
+	return resp, nil
+}
+
+// resampleFreqToTimeframe maps the resample strings our /stocks handler
+// already accepts (mirroring Tiingo's resampleFreq) onto Alpaca timeframes.
+func resampleFreqToTimeframe(freq string) string {
+	switch freq {
+	case "daily", "":
+		return "1Day"
+	case "weekly":
+		return "1Week"
+	case "monthly":
+		return "1Month"
+	default:
+		return freq
+	}
+}
+
+func (p *AlpacaProvider) HistoricalPrices(ctx context.Context, ticker, start, freq string) ([]PricePoint, error) {
+	if p.keyID == "" || p.secretKey == "" {
+		return nil, fmt.Errorf("alpaca: missing API credentials")
+	}
+
+	url := fmt.Sprintf(
+		"%s/v2/stocks/%s/bars?start=%s&timeframe=%s",
+		alpacaDataBaseURL, ticker, start, resampleFreqToTimeframe(freq),
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("alpaca: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed alpacaBarsResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("alpaca: decode response: %w", err)
+	}
+	if len(parsed.Bars) == 0 {
+		return nil, ErrNoData
+	}
+
+	prices := make([]PricePoint, 0, len(parsed.Bars))
+	for _, bar := range parsed.Bars {
+		prices = append(prices, PricePoint{Date: bar.Timestamp, Close: bar.Close})
+	}
+	return prices, nil
+}
+
+func (p *AlpacaProvider) LatestPrice(ctx context.Context, ticker string) (LastPrice, error) {
+	if p.keyID == "" || p.secretKey == "" {
+		return LastPrice{}, fmt.Errorf("alpaca: missing API credentials")
+	}
+
+	url := fmt.Sprintf("%s/v2/stocks/%s/quotes/latest", alpacaDataBaseURL, ticker)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return LastPrice{}, err
+	}
+
+	resp, err := p.do(req)
+	if err != nil {
+		return LastPrice{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return LastPrice{}, fmt.Errorf("alpaca: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return LastPrice{}, err
+	}
+
+	var parsed alpacaQuoteResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return LastPrice{}, fmt.Errorf("alpaca: decode response: %w", err)
+	}
+	if parsed.Quote.AskPrice == 0 && parsed.Quote.BidPrice == 0 {
+		return LastPrice{}, ErrNoData
+	}
+
+	// Alpaca's latest quote endpoint has no notion of "previous close", so
+	// use the mid of bid/ask for both fields; callers only fall back to
+	// PrevClose when Last is nil, which never happens for this provider.
+	mid := (parsed.Quote.AskPrice + parsed.Quote.BidPrice) / 2
+	return LastPrice{Last: &mid, PrevClose: mid}, nil
+}
+
+func (p *AlpacaProvider) HealthCheck(ctx context.Context) error {
+	if p.keyID == "" || p.secretKey == "" {
+		return fmt.Errorf("alpaca: missing API credentials")
+	}
+	_, err := p.LatestPrice(ctx, "SPY")
+	return err
+}
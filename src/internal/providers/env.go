@@ -0,0 +1,30 @@
+package providers
+
+import (
+	"fmt"
+	"os"
+)
+
+// FromEnv builds a Provider based on the MARKET_PROVIDER env var:
+//
+//	tiingo        - TiingoProvider only (default)
+//	alpaca        - AlpacaProvider only
+//	multi         - TiingoProvider, falling back to AlpacaProvider
+//
+// Credentials are read from TIINGO_API_KEY, ALPACA_API_KEY_ID and
+// ALPACA_API_SECRET_KEY.
+func FromEnv() (Provider, error) {
+	tiingo := NewTiingoProvider(os.Getenv("TIINGO_API_KEY"))
+	alpaca := NewAlpacaProvider(os.Getenv("ALPACA_API_KEY_ID"), os.Getenv("ALPACA_API_SECRET_KEY"))
+
+	switch os.Getenv("MARKET_PROVIDER") {
+	case "", "tiingo":
+		return tiingo, nil
+	case "alpaca":
+		return alpaca, nil
+	case "multi":
+		return NewMultiProvider(tiingo, alpaca), nil
+	default:
+		return nil, fmt.Errorf("providers: unknown MARKET_PROVIDER %q", os.Getenv("MARKET_PROVIDER"))
+	}
+}
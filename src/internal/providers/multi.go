@@ -0,0 +1,45 @@
+package providers
+
+import (
+	"context"
+	"log"
+)
+
+// MultiProvider tries primary first and falls back to secondary if primary
+// errors or returns no data. It's useful for riding out a vendor outage
+// without code changes on either side of the handler.
+type MultiProvider struct {
+	primary   Provider
+	secondary Provider
+}
+
+// NewMultiProvider builds a MultiProvider that prefers primary and falls
+// back to secondary.
+func NewMultiProvider(primary, secondary Provider) *MultiProvider {
+	return &MultiProvider{primary: primary, secondary: secondary}
+}
+
+func (m *MultiProvider) HistoricalPrices(ctx context.Context, ticker, start, freq string) ([]PricePoint, error) {
+	prices, err := m.primary.HistoricalPrices(ctx, ticker, start, freq)
+	if err == nil && len(prices) > 0 {
+		return prices, nil
+	}
+	log.Printf("⚠️  primary provider failed for HistoricalPrices(%s): %v — falling back", ticker, err)
+	return m.secondary.HistoricalPrices(ctx, ticker, start, freq)
+}
+
+func (m *MultiProvider) LatestPrice(ctx context.Context, ticker string) (LastPrice, error) {
+	price, err := m.primary.LatestPrice(ctx, ticker)
+	if err == nil {
+		return price, nil
+	}
+	log.Printf("⚠️  primary provider failed for LatestPrice(%s): %v — falling back", ticker, err)
+	return m.secondary.LatestPrice(ctx, ticker)
+}
+
+func (m *MultiProvider) HealthCheck(ctx context.Context) error {
+	if err := m.primary.HealthCheck(ctx); err == nil {
+		return nil
+	}
+	return m.secondary.HealthCheck(ctx)
+}
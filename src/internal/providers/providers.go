@@ -0,0 +1,45 @@
+// Package providers abstracts the market-data backend behind a small
+// interface so the REST handlers in main.go don't talk to Tiingo (or any
+// other vendor) directly. This makes it possible to fail over between
+// vendors and to test handlers against a fake implementation.
+package providers
+
+import (
+	"context"
+	"fmt"
+)
+
+// PricePoint represents a single entry from a historical daily-bar series.
+type PricePoint struct {
+	Date  string  `json:"date"`
+	Close float64 `json:"close"`
+}
+
+// PriceResponse is what our Go service returns to the frontend/backend
+// when returning stock price historical data.
+type PriceResponse []PricePoint
+
+// LastPrice is the most recent traded price for a ticker, along with the
+// previous close to fall back on when the market is closed.
+type LastPrice struct {
+	Last      *float64 `json:"last"`
+	PrevClose float64  `json:"prevClose"`
+}
+
+// Provider is implemented by each market-data backend (Tiingo, Alpaca, ...).
+// Handlers in main.go depend only on this interface. Every method takes a
+// context so callers can abort in-flight upstream calls on shutdown.
+type Provider interface {
+	// HistoricalPrices returns daily bars for ticker starting at start,
+	// resampled to freq (provider-specific resample string, e.g. "daily").
+	HistoricalPrices(ctx context.Context, ticker, start, freq string) ([]PricePoint, error)
+	// LatestPrice returns the most recent trade/quote price for ticker.
+	LatestPrice(ctx context.Context, ticker string) (LastPrice, error)
+	// HealthCheck verifies the provider is reachable and configured.
+	HealthCheck(ctx context.Context) error
+}
+
+// ErrNoData indicates the upstream provider returned no usable data for a
+// ticker, distinct from a transport/HTTP error. MultiProvider treats this
+// the same as a transport error and falls back to the next provider.
+var ErrNoData = fmt.Errorf("no data returned by provider")
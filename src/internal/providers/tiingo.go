@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/portfolio-insights/market-service/internal/metrics"
+)
+
+// TiingoProvider implements Provider against the Tiingo REST API.
+type TiingoProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewTiingoProvider builds a TiingoProvider. apiKey is the Tiingo API token.
+// Requests are routed through an instrumented transport so upstream call
+// counts/latency show up as tiingo_requests_total / tiingo_request_duration_seconds.
+func NewTiingoProvider(apiKey string) *TiingoProvider {
+	return &TiingoProvider{apiKey: apiKey, client: &http.Client{Transport: metrics.NewTiingoTransport(nil)}}
+}
+
+func (p *TiingoProvider) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, classifyNetErr("tiingo", err)
+	}
+	return resp, nil
+}
+
+func (p *TiingoProvider) HistoricalPrices(ctx context.Context, ticker, start, freq string) ([]PricePoint, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("tiingo: missing API key")
+	}
+
+	url := fmt.Sprintf(
+		"https://api.tiingo.com/tiingo/daily/%s/prices?startDate=%s&resampleFreq=%s&token=%s",
+		ticker, start, freq, p.apiKey,
+	)
+	resp, err := p.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tiingo: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var prices []PricePoint
+	if err := json.Unmarshal(body, &prices); err != nil {
+		return nil, fmt.Errorf("tiingo: decode response: %w", err)
+	}
+	if len(prices) == 0 {
+		return nil, ErrNoData
+	}
+	return prices, nil
+}
+
+func (p *TiingoProvider) LatestPrice(ctx context.Context, ticker string) (LastPrice, error) {
+	if p.apiKey == "" {
+		return LastPrice{}, fmt.Errorf("tiingo: missing API key")
+	}
+
+	url := fmt.Sprintf("https://api.tiingo.com/iex/%s?token=%s", ticker, p.apiKey)
+	resp, err := p.get(ctx, url)
+	if err != nil {
+		return LastPrice{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return LastPrice{}, fmt.Errorf("tiingo: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return LastPrice{}, err
+	}
+
+	var result []LastPrice
+	if err := json.Unmarshal(body, &result); err != nil {
+		return LastPrice{}, fmt.Errorf("tiingo: decode response: %w", err)
+	}
+	if len(result) == 0 {
+		return LastPrice{}, ErrNoData
+	}
+	return result[0], nil
+}
+
+func (p *TiingoProvider) HealthCheck(ctx context.Context) error {
+	if p.apiKey == "" {
+		return fmt.Errorf("tiingo: missing API key")
+	}
+
+	// SPY is used as a stable, always-listed ticker to probe connectivity.
+	url := fmt.Sprintf("https://api.tiingo.com/tiingo/daily/SPY/prices?token=%s", p.apiKey)
+	resp, err := p.get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tiingo: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// classifyNetErr turns timeouts into a distinguishable error so callers can
+// still tell timeouts apart from other transport failures if they need to.
+func classifyNetErr(provider string, err error) error {
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return fmt.Errorf("%s: network timeout: %w", provider, err)
+	}
+	return fmt.Errorf("%s: network error: %w", provider, err)
+}
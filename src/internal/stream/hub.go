@@ -0,0 +1,380 @@
+// Package stream implements a WebSocket fan-out gateway for live quotes.
+//
+// A single Hub multiplexes updates from one poller goroutine per subscribed
+// ticker out to every Client that has subscribed to it. Clients speak a tiny
+// control protocol ({"action":"subscribe","tickers":[...]}) and receive
+// Tiingo IEX-style quote frames in return.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/portfolio-insights/market-service/internal/providers"
+)
+
+const (
+	// pollInterval controls how often each per-ticker poller asks the
+	// provider for the latest price.
+	pollInterval = 2 * time.Second
+	// sendBuffer is the per-client outbound queue depth. A client that can't
+	// keep up with its buffer is treated as a slow consumer and dropped.
+	sendBuffer = 16
+	// pingInterval/pongWait implement the heartbeat and its deadline.
+	pingInterval = 30 * time.Second
+	pongWait     = 60 * time.Second
+	// writeWait bounds each individual write. It must be set before every
+	// write because the connection is hijacked out of net/http once
+	// upgraded: the server's own WriteTimeout is applied exactly once, to
+	// the upgrade request, and is never refreshed for the lifetime of the
+	// hijacked connection.
+	writeWait = 10 * time.Second
+)
+
+// Quote is the frame shape emitted to subscribers, modeled on Tiingo/Alpaca
+// style streaming payloads: {"T":"t","S":"AAPL","p":189.42,"t":"..."}
+type Quote struct {
+	Type   string  `json:"T"`
+	Symbol string  `json:"S"`
+	Price  float64 `json:"p"`
+	Time   string  `json:"t"`
+}
+
+// controlMessage is what clients send to (un)subscribe from tickers.
+type controlMessage struct {
+	Action  string   `json:"action"`
+	Tickers []string `json:"tickers"`
+}
+
+type subscription struct {
+	client *Client
+	ticker string
+}
+
+type tickerUpdate struct {
+	ticker string
+	data   []byte
+}
+
+// Hub owns the subscriber registry and one poller per actively-subscribed
+// ticker. Create with NewHub and run it with Run in its own goroutine.
+type Hub struct {
+	provider providers.Provider
+
+	// ctx is the Run context, stashed so per-ticker pollers can cancel their
+	// upstream call promptly on shutdown. Only valid once Run has started.
+	ctx context.Context
+
+	subscribers map[string]map[*Client]bool // ticker -> subscribed clients
+	pollerStop  map[string]chan struct{}    // ticker -> poller shutdown signal
+
+	register   chan subscription
+	unregister chan subscription
+	broadcast  chan tickerUpdate
+	leave      chan *Client
+}
+
+// NewHub builds an idle Hub backed by provider. Call Run to start servicing it.
+func NewHub(provider providers.Provider) *Hub {
+	return &Hub{
+		provider:    provider,
+		subscribers: make(map[string]map[*Client]bool),
+		pollerStop:  make(map[string]chan struct{}),
+		register:    make(chan subscription),
+		unregister:  make(chan subscription),
+		broadcast:   make(chan tickerUpdate, 64),
+		leave:       make(chan *Client),
+	}
+}
+
+// Run services the Hub's channels until ctx is done. It must only be
+// called once, typically from a goroutine started in main.
+func (h *Hub) Run(ctx context.Context) {
+	h.ctx = ctx
+	for {
+		select {
+		case <-ctx.Done():
+			for ticker, done := range h.pollerStop {
+				close(done)
+				delete(h.pollerStop, ticker)
+			}
+			h.drain()
+			return
+		case sub := <-h.register:
+			clients, ok := h.subscribers[sub.ticker]
+			if !ok {
+				clients = make(map[*Client]bool)
+				h.subscribers[sub.ticker] = clients
+				h.startPoller(sub.ticker)
+			}
+			clients[sub.client] = true
+		case sub := <-h.unregister:
+			h.removeSubscriber(sub.ticker, sub.client)
+		case c := <-h.leave:
+			if c.left {
+				continue
+			}
+			c.left = true
+			for ticker := range c.subscribedTickers() {
+				h.removeSubscriber(ticker, c)
+			}
+			close(c.send)
+		case update := <-h.broadcast:
+			for c := range h.subscribers[update.ticker] {
+				select {
+				case c.send <- update.data:
+				default:
+					// Slow consumer: drop it rather than block the broadcaster.
+					log.Printf("⚠️  dropping slow stream client for %s", update.ticker)
+					go func(c *Client) { h.leave <- c }(c)
+				}
+			}
+		}
+	}
+}
+
+// drain keeps servicing h.leave/h.register/h.unregister after ctx is done,
+// so readPump goroutines unblocked by ServeWS's shutdown-triggered
+// conn.Close() can still deliver their leave and return instead of
+// blocking forever on a channel nobody reads. It returns once every
+// client has left, or immediately if none were connected.
+func (h *Hub) drain() {
+	live := make(map[*Client]bool)
+	for _, clients := range h.subscribers {
+		for c := range clients {
+			live[c] = true
+		}
+	}
+	remaining := len(live)
+	if remaining == 0 {
+		return
+	}
+	for remaining > 0 {
+		select {
+		case sub := <-h.register:
+			// Connections racing the shutdown signal: ack and drop.
+			_ = sub
+		case sub := <-h.unregister:
+			_ = sub
+		case c := <-h.leave:
+			if c.left {
+				continue
+			}
+			c.left = true
+			for ticker := range c.subscribedTickers() {
+				h.removeSubscriber(ticker, c)
+			}
+			close(c.send)
+			remaining--
+		}
+	}
+}
+
+func (h *Hub) removeSubscriber(ticker string, c *Client) {
+	clients, ok := h.subscribers[ticker]
+	if !ok {
+		return
+	}
+	delete(clients, c)
+	if len(clients) == 0 {
+		delete(h.subscribers, ticker)
+		if done, ok := h.pollerStop[ticker]; ok {
+			close(done)
+			delete(h.pollerStop, ticker)
+		}
+	}
+}
+
+// startPoller launches the per-ticker goroutine that polls the provider's
+// LatestPrice and feeds the broadcast channel. Callers must hold no lock; Run is the only
+// goroutine touching h.subscribers/h.pollerStop, so this is safe to call
+// from within Run's select loop.
+func (h *Hub) startPoller(ticker string) {
+	done := make(chan struct{})
+	h.pollerStop[ticker] = done
+	go func() {
+		ticker := ticker
+		t := time.NewTicker(pollInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				data, err := h.fetchQuote(ticker)
+				if err != nil {
+					log.Printf("⚠️  stream: poll %s: %v", ticker, err)
+					continue
+				}
+				h.broadcast <- tickerUpdate{ticker: ticker, data: data}
+			}
+		}
+	}()
+}
+
+func (h *Hub) fetchQuote(ticker string) ([]byte, error) {
+	last, err := h.provider.LatestPrice(h.ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	price := last.PrevClose
+	if last.Last != nil {
+		price = *last.Last
+	}
+	q := Quote{
+		Type:   "t",
+		Symbol: ticker,
+		Price:  price,
+		Time:   time.Now().UTC().Format(time.RFC3339),
+	}
+	return json.Marshal(q)
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// Streaming is consumed by our own frontend from a handful of known
+	// origins in production; CheckOrigin is relaxed here the same way the
+	// REST handlers have no CORS restrictions today.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Client wraps one subscriber's WebSocket connection.
+type Client struct {
+	hub  *Hub
+	conn *websocket.Conn
+	send chan []byte
+
+	mu      sync.Mutex
+	tickers map[string]bool
+
+	// left is set once this client has been fully processed off h.leave.
+	// Only Run (and drain, which only runs after Run's select loop has
+	// exited) ever reads or writes it, so it needs no locking of its own.
+	// Without it, a client that reaches h.leave twice — e.g. dropped as a
+	// slow consumer, which unblocks its writePump and, via conn.Close(),
+	// its readPump, whose own deferred leave send fires a second time —
+	// would hit a double close(c.send) and panic the process.
+	left bool
+}
+
+func (c *Client) subscribedTickers() map[string]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]bool, len(c.tickers))
+	for t := range c.tickers {
+		out[t] = true
+	}
+	return out
+}
+
+// ServeWS upgrades the request to a WebSocket and runs the client's
+// read/write pumps until it disconnects or ctx is done (e.g. on server
+// shutdown), at which point its connection is force-closed to unblock the
+// read pump.
+func ServeWS(ctx context.Context, hub *Hub, w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("⚠️  stream: upgrade failed: %v", err)
+		return
+	}
+	c := &Client{
+		hub:     hub,
+		conn:    conn,
+		send:    make(chan []byte, sendBuffer),
+		tickers: make(map[string]bool),
+	}
+
+	stopWatch := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.conn.Close()
+		case <-stopWatch:
+		}
+	}()
+
+	go c.writePump()
+	c.readPump()
+	close(stopWatch)
+}
+
+func (c *Client) readPump() {
+	defer func() {
+		c.hub.leave <- c
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var ctrl controlMessage
+		if err := json.Unmarshal(msg, &ctrl); err != nil {
+			continue
+		}
+
+		switch ctrl.Action {
+		case "subscribe":
+			c.mu.Lock()
+			for _, t := range ctrl.Tickers {
+				c.tickers[t] = true
+			}
+			c.mu.Unlock()
+			for _, t := range ctrl.Tickers {
+				c.hub.register <- subscription{client: c, ticker: t}
+			}
+		case "unsubscribe":
+			c.mu.Lock()
+			for _, t := range ctrl.Tickers {
+				delete(c.tickers, t)
+			}
+			c.mu.Unlock()
+			for _, t := range ctrl.Tickers {
+				c.hub.unregister <- subscription{client: c, ticker: t}
+			}
+		}
+	}
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-c.send:
+			if !ok {
+				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
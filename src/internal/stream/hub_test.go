@@ -0,0 +1,163 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/portfolio-insights/market-service/internal/providers"
+)
+
+// fakeProvider returns a fixed price for every ticker, satisfying
+// providers.Provider for tests.
+type fakeProvider struct {
+	price float64
+}
+
+func (p *fakeProvider) HistoricalPrices(ctx context.Context, ticker, start, freq string) ([]providers.PricePoint, error) {
+	return nil, nil
+}
+
+func (p *fakeProvider) LatestPrice(ctx context.Context, ticker string) (providers.LastPrice, error) {
+	last := p.price
+	return providers.LastPrice{Last: &last}, nil
+}
+
+func (p *fakeProvider) HealthCheck(ctx context.Context) error { return nil }
+
+func newTestHub() (*Hub, context.Context, context.CancelFunc) {
+	h := NewHub(&fakeProvider{price: 100})
+	ctx, cancel := context.WithCancel(context.Background())
+	go h.Run(ctx)
+	return h, ctx, cancel
+}
+
+func TestHubBroadcastsToSubscribedClients(t *testing.T) {
+	h, _, cancel := newTestHub()
+	defer cancel()
+
+	c := &Client{hub: h, send: make(chan []byte, sendBuffer), tickers: make(map[string]bool)}
+	h.register <- subscription{client: c, ticker: "AAPL"}
+
+	h.broadcast <- tickerUpdate{ticker: "AAPL", data: []byte(`{"S":"AAPL"}`)}
+
+	select {
+	case msg := <-c.send:
+		if string(msg) != `{"S":"AAPL"}` {
+			t.Fatalf("unexpected message: %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for broadcast")
+	}
+}
+
+func TestHubDoesNotBroadcastToOtherTickers(t *testing.T) {
+	h, _, cancel := newTestHub()
+	defer cancel()
+
+	c := &Client{hub: h, send: make(chan []byte, sendBuffer), tickers: make(map[string]bool)}
+	h.register <- subscription{client: c, ticker: "AAPL"}
+
+	h.broadcast <- tickerUpdate{ticker: "MSFT", data: []byte(`{"S":"MSFT"}`)}
+
+	select {
+	case msg := <-c.send:
+		t.Fatalf("unexpected message delivered to unrelated subscriber: %s", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHubDropsSlowConsumer(t *testing.T) {
+	h, _, cancel := newTestHub()
+	defer cancel()
+
+	c := &Client{hub: h, send: make(chan []byte, sendBuffer), tickers: make(map[string]bool)}
+	h.register <- subscription{client: c, ticker: "AAPL"}
+
+	// Fill the client's buffer, then push one more update to force the
+	// broadcaster into its slow-consumer drop path.
+	for i := 0; i < sendBuffer; i++ {
+		h.broadcast <- tickerUpdate{ticker: "AAPL", data: []byte("x")}
+	}
+	h.broadcast <- tickerUpdate{ticker: "AAPL", data: []byte("y")}
+
+	select {
+	case _, ok := <-c.send:
+		for ok {
+			_, ok = <-c.send
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for client's send channel to close after being dropped")
+	}
+}
+
+// TestHubDropsSlowConsumerOverRealConnection drives a real ServeWS
+// WebSocket connection through the slow-consumer drop path. A client that
+// never reads its socket forces writePump to block mid-write, backing up
+// its send channel; the hub then drops it via h.leave. That unblocks
+// writePump (close(c.send) -> close frame -> return -> conn.Close()),
+// which in turn unblocks readPump's ReadMessage, whose deferred leave send
+// fires a second time for the same client. Without idempotent handling of
+// the leave case, the second close(c.send) panics and takes the whole
+// process down with it.
+func TestHubDropsSlowConsumerOverRealConnection(t *testing.T) {
+	h := NewHub(&fakeProvider{price: 100})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go h.Run(ctx)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWS(ctx, h, w, r)
+	}))
+	defer srv.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/stream"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(controlMessage{Action: "subscribe", Tickers: []string{"AAPL"}}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	// Give readPump time to process the subscribe control message before
+	// flooding the broadcast channel.
+	time.Sleep(50 * time.Millisecond)
+
+	// Never read from conn: flood enough large updates to back up both the
+	// OS socket buffer and the client's sendBuffer-sized channel, forcing
+	// the hub into its slow-consumer drop path.
+	big := strings.Repeat("x", 512)
+	for i := 0; i < 5000; i++ {
+		h.broadcast <- tickerUpdate{ticker: "AAPL", data: []byte(big)}
+	}
+
+	// If the bug described above is present, the hub's Run goroutine
+	// panics well before this deadline and takes the test binary with it.
+	time.Sleep(500 * time.Millisecond)
+}
+
+func TestHubUnregisterStopsBroadcast(t *testing.T) {
+	h, _, cancel := newTestHub()
+	defer cancel()
+
+	c := &Client{hub: h, send: make(chan []byte, sendBuffer), tickers: make(map[string]bool)}
+	h.register <- subscription{client: c, ticker: "AAPL"}
+	h.unregister <- subscription{client: c, ticker: "AAPL"}
+
+	h.broadcast <- tickerUpdate{ticker: "AAPL", data: []byte("should not arrive")}
+
+	select {
+	case msg, ok := <-c.send:
+		if ok {
+			t.Fatalf("unexpected message after unsubscribe: %s", msg)
+		}
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,113 @@
+// Package tlsconfig configures optional TLS termination for the HTTP
+// server, controlled entirely by env vars so the service can be deployed
+// directly to the edge without an external reverse proxy.
+package tlsconfig
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Mode selects how (or whether) the server terminates TLS.
+type Mode string
+
+const (
+	// ModeOff serves plain HTTP; TLS is handled upstream, if at all.
+	ModeOff Mode = "off"
+	// ModeManual serves TLS from a certificate/key pair on disk.
+	ModeManual Mode = "manual"
+	// ModeAutocert provisions and renews certificates automatically via
+	// Let's Encrypt.
+	ModeAutocert Mode = "autocert"
+)
+
+// Config describes how to terminate TLS, built by FromEnv.
+type Config struct {
+	Mode Mode
+
+	// Manual mode.
+	CertFile string
+	KeyFile  string
+
+	// Autocert mode.
+	Domains  []string
+	CacheDir string
+	Email    string
+}
+
+// FromEnv builds a Config from TLS_MODE (off|manual|autocert, default off)
+// and its mode-specific env vars:
+//
+//	manual    - TLS_CERT_FILE, TLS_KEY_FILE
+//	autocert  - TLS_DOMAINS (comma-separated allow-list), TLS_CACHE_DIR
+//	            (default "./tls-cache"), TLS_EMAIL (optional, used for
+//	            Let's Encrypt expiry notices)
+func FromEnv() (*Config, error) {
+	mode := Mode(os.Getenv("TLS_MODE"))
+	if mode == "" {
+		mode = ModeOff
+	}
+
+	cfg := &Config{Mode: mode}
+
+	switch mode {
+	case ModeOff:
+		return cfg, nil
+
+	case ModeManual:
+		cfg.CertFile = os.Getenv("TLS_CERT_FILE")
+		cfg.KeyFile = os.Getenv("TLS_KEY_FILE")
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, fmt.Errorf("tlsconfig: TLS_MODE=manual requires TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+		return cfg, nil
+
+	case ModeAutocert:
+		raw := os.Getenv("TLS_DOMAINS")
+		if raw == "" {
+			return nil, fmt.Errorf("tlsconfig: TLS_MODE=autocert requires TLS_DOMAINS")
+		}
+		for _, d := range strings.Split(raw, ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				cfg.Domains = append(cfg.Domains, d)
+			}
+		}
+		cfg.CacheDir = os.Getenv("TLS_CACHE_DIR")
+		if cfg.CacheDir == "" {
+			cfg.CacheDir = "./tls-cache"
+		}
+		cfg.Email = os.Getenv("TLS_EMAIL")
+		return cfg, nil
+
+	default:
+		return nil, fmt.Errorf("tlsconfig: unknown TLS_MODE %q", mode)
+	}
+}
+
+// Manager builds the autocert.Manager described by c. Only valid when
+// c.Mode is ModeAutocert.
+func (c *Config) Manager() *autocert.Manager {
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(c.Domains...),
+		Cache:      autocert.DirCache(c.CacheDir),
+		Email:      c.Email,
+	}
+}
+
+// ChallengeServer builds the :80 listener that answers the ACME HTTP-01
+// challenge for m and 301-redirects all other traffic to HTTPS.
+func ChallengeServer(m *autocert.Manager) *http.Server {
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := "https://" + r.Host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+	return &http.Server{
+		Addr:    ":80",
+		Handler: m.HTTPHandler(redirect),
+	}
+}
@@ -11,36 +11,28 @@ package main // Every standalone executable Go program must have package main
 
 // Built-in Go packages
 import (
+	"context"                  // For cancelling the alert engine's background loop
 	"encoding/json"            // For converting structs to JSON (and vice versa)
+	"errors"                   // For matching providers.ErrNoData and alerts.ErrNotFound
 	"fmt"                      // For string formatting
 	"github.com/joho/godotenv" // For loading a .env file
-	"io"                       // For reading from response bodies
 	"log"                      // For printing logs to the terminal
-	"net"                      // For extended API request error handling
 	"net/http"                 // For making and serving HTTP requests
 	"os"                       // For reading environment variables
+	"os/signal"                // For intercepting SIGINT/SIGTERM
 	"strconv"                  // For converting strings to numbers (e.g. price query param to float)
+	"strings"                  // For parsing the alert id out of /alerts/{id}
+	"syscall"                  // For the SIGTERM signal constant
 	"time"                     // For time values, used in network timeouts
-)
-
-// PricePoint represents a single entry from the Tiingo historical price API
-// Note that fields are in PascalCase since we want to export them
-// Note that JSON object keys map to Go struct fields
-type PricePoint struct {
-	Date  string  `json:"date"`  // Struct tag for JSON marshalling/unmarshalling
-	Close float64 `json:"close"` // Field must be exported (capitalized) to be included in JSON
-}
-
-// PriceResponse is what our Go service returns to the frontend/backend
-// when returning stock price historical data
-type PriceResponse []PricePoint
 
-// LastPrice is used in our alert validation endpoint to store the most
-// recent stock price
-type LastPrice struct {
-	Last      *float64 `json:"last"` // Pointer used to check if value exists in /check-alert implementation
-	PrevClose float64  `json:"prevClose"`
-}
+	"github.com/portfolio-insights/market-service/internal/alerts"    // Persistent alert store + evaluation engine
+	"github.com/portfolio-insights/market-service/internal/cache"     // TTL cache in front of upstream provider calls
+	"github.com/portfolio-insights/market-service/internal/lifecycle" // Tracks long-lived handlers for graceful shutdown
+	"github.com/portfolio-insights/market-service/internal/metrics"   // Prometheus collectors and instrumentation
+	"github.com/portfolio-insights/market-service/internal/providers" // Market-data backends (Tiingo, Alpaca, ...)
+	"github.com/portfolio-insights/market-service/internal/stream"    // Live quote WebSocket hub
+	"github.com/portfolio-insights/market-service/internal/tlsconfig" // Optional TLS termination (manual or autocert)
+)
 
 // Load .env file
 func init() {
@@ -61,43 +53,73 @@ func loggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// registerRoute wires pattern up with the standard middleware stack: request
+// logging, then Prometheus request-count/latency instrumentation.
+func registerRoute(pattern string, handler http.HandlerFunc) {
+	http.HandleFunc(pattern, metrics.Instrument(pattern, loggingMiddleware(handler)))
+}
+
 func main() {
 	// Log server startup
 	log.Println("🚀 Starting Market Service...")
 
+	// rootCtx is cancelled the moment a SIGINT/SIGTERM arrives. Every
+	// long-lived handler (the stream hub's pollers, WebSocket clients, the
+	// alert engine) derives from it, so shutdown aborts in-flight upstream
+	// calls promptly instead of waiting for them to time out on their own.
+	rootCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	// conns tracks every long-lived handler so graceful shutdown can wait
+	// for them to drain before reporting what was force-closed.
+	conns := lifecycle.New()
+
+	// Pick the market-data backend (Tiingo, Alpaca, or both) based on the
+	// MARKET_PROVIDER env var. Every handler below depends only on the
+	// providers.Provider interface, not on a specific vendor.
+	provider, err := providers.FromEnv()
+	if err != nil {
+		log.Fatalf("⚠️  %v", err)
+	}
+
+	// dataCache sits in front of the provider calls in /stocks and
+	// /check-alert, selected by CACHE_BACKEND so multiple replicas can
+	// share a Redis-backed cache instead of each keeping its own.
+	dataCache := cache.FromEnv()
+
+	// --------- Live Quote Streaming ---------
+
+	// The Hub owns one poller goroutine per actively-subscribed ticker and
+	// fans out updates to every subscribed client, stopping when rootCtx is
+	// cancelled.
+	hub := stream.NewHub(provider)
+	go hub.Run(rootCtx)
+
+	// Define /stream route
+	// Clients connect, then send {"action":"subscribe","tickers":["AAPL"]}
+	// control messages to start receiving live quote frames for those
+	// tickers, and {"action":"unsubscribe",...} to stop.
+	registerRoute("/stream", func(w http.ResponseWriter, r *http.Request) {
+		conns.Add(1)
+		defer conns.Done()
+		stream.ServeWS(rootCtx, hub, w, r)
+	})
+
 	// --------- Health Check ---------
 
 	// Define /health route
-	// This verifies that the Go microservice is running and that it is connected to the Tiingo API
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	// This verifies that the Go microservice is running and that its
+	// configured market-data provider is reachable.
+	registerRoute("/health", func(w http.ResponseWriter, r *http.Request) {
 		log.Println("Health check requested")
-		apiKey := os.Getenv("TIINGO_API_KEY")
-		if apiKey == "" {
-			GenerateError(w, "Missing API key.", http.StatusInternalServerError)
-			return
-		}
 
-		// Make lightweight request to Tiingo using stable ticker (SPY)
-		client := &http.Client{}
-		url := fmt.Sprintf("https://api.tiingo.com/tiingo/daily/SPY/prices?token=%s", apiKey)
-		resp, err := client.Get(url)
-		if err != nil {
-			// Check if error is a net.Error and is a timeout
-			// net.Error provides for expanded error handling, including checking type of error (e.g. timeout)
-			// The standard syntax for type assertions is v, ok := x.(T)
-			netErr, ok := err.(net.Error)
-			if ok && netErr.Timeout() {
-				GenerateError(w, "Network timeout.", http.StatusGatewayTimeout)
-				return
-			}
-
-			// Other network-related error
-			GenerateError(w, "Network error.", http.StatusBadGateway)
+		if err := provider.HealthCheck(r.Context()); err != nil {
+			log.Println("ERROR: health check failed -", err)
+			GenerateError(w, "Provider health check failed.", http.StatusBadGateway)
 			return
 		}
-		defer resp.Body.Close() // Clean up response body
 
-		// Confirm service and API connectivity
+		// Confirm service and provider connectivity
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"health": true}`))
 	})
@@ -107,7 +129,7 @@ func main() {
 	// Define /stocks route
 	// Note that * is the Go pointer operator
 	// w and r are analogous to res and req in Express.js, respectively
-	http.HandleFunc("/stocks", func(w http.ResponseWriter, r *http.Request) {
+	registerRoute("/stocks", func(w http.ResponseWriter, r *http.Request) {
 		// Extract queries from GET request
 		// Go idiom: := declares and initializes a variable with inferred type
 		ticker := r.URL.Query().Get("ticker")
@@ -124,65 +146,54 @@ func main() {
 			return
 		}
 
-		// Load in Tiingo API key
-		apiKey := os.Getenv("TIINGO_API_KEY")
-		if apiKey == "" {
-			GenerateError(w, "Missing API key.", http.StatusInternalServerError)
-			return
-		}
-
-		// Send GET request to Tiingo API to retrieve stock price history
-		client := &http.Client{}
-		url := fmt.Sprintf(
-			"https://api.tiingo.com/tiingo/daily/%s/prices?startDate=%s&resampleFreq=%s&token=%s",
-			ticker,
-			startDate,
-			resampleFreq,
-			apiKey,
-		)
-		resp, err := client.Get(url)
-		// OK response is expected to have no error and a 200 status code
-		if err != nil {
-			// Check if error is a net.Error and is a timeout
-			// net.Error provides for expanded error handling, including checking type of error (e.g. timeout)
-			// The standard syntax for type assertions is v, ok := x.(T)
-			netErr, ok := err.(net.Error)
-			if ok && netErr.Timeout() {
-				GenerateError(w, "Network timeout.", http.StatusGatewayTimeout)
-				return
-			}
+		w.Header().Set("Content-Type", "application/json")
 
-			// Other network-related error
-			GenerateError(w, "Network error.", http.StatusBadGateway)
+		cacheKey := fmt.Sprintf("stocks|%s|%s|%s", ticker, startDate, resampleFreq)
+		if cached, ok := dataCache.Get(cacheKey); ok {
+			metrics.CacheHitsTotal.WithLabelValues("/stocks").Inc()
+			w.Header().Set("X-Cache", "HIT")
+			w.Write(cached)
 			return
 		}
-		// "defer" schedules a function to run after the current function finishes
-		defer resp.Body.Close() // Ensures the response body is closed when this function ends
-
-		// Extract and format information from price history retrieval
-		// As in other languages, _ is used to indicate an unused variable
-		body, _ := io.ReadAll(resp.Body)
-
-		var tiingoPrices []PricePoint
-		// Unmarshalling converts JSON bytes into native Go data structures
-		// Error is ignored here for brevity, but should be handled in production.
-		// &tiingoPrices passes a pointer so json.Unmarshal can populate the slice in place
-		json.Unmarshal(body, &tiingoPrices)
-		if len(tiingoPrices) == 0 {
+		metrics.CacheMissesTotal.WithLabelValues("/stocks").Inc()
+		w.Header().Set("X-Cache", "MISS")
+
+		// Fetch stock price history from the configured market-data provider
+		prices, err := provider.HistoricalPrices(r.Context(), ticker, startDate, resampleFreq)
+		if errors.Is(err, providers.ErrNoData) {
 			errMsg := fmt.Sprintf("No price data found for ticker: %s", ticker)
 			log.Println("WARN:", errMsg)
 			GenerateError(w, errMsg, http.StatusNotFound)
 			return
 		}
-		log.Printf("Successfully retrieved %d price points for ticker: %s", len(tiingoPrices), ticker)
-		// Encode to JSON and return if no errors encountered
-		w.Header().Set("Content-Type", "application/json") // Set headers
-		json.NewEncoder(w).Encode(tiingoPrices)            // Format as JSON
+		if err != nil {
+			log.Println("ERROR: provider request failed -", err)
+			GenerateError(w, "Error fetching price data.", http.StatusBadGateway)
+			return
+		}
+
+		log.Printf("Successfully retrieved %d price points for ticker: %s", len(prices), ticker)
+		body, err := json.Marshal(prices)
+		if err != nil {
+			log.Println("ERROR: failed to encode prices -", err)
+			GenerateError(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+
+		// Daily bars barely move within a quarter hour; intraday bars do, so
+		// cache those for a much shorter window.
+		ttl := 30 * time.Second
+		if resampleFreq == "daily" {
+			ttl = 15 * time.Minute
+		}
+		dataCache.Set(cacheKey, body, ttl)
+
+		w.Write(body)
 	})
 
 	// --------- New Alert Validity Check ---------
 
-	http.HandleFunc("/check-alert", func(w http.ResponseWriter, r *http.Request) {
+	registerRoute("/check-alert", func(w http.ResponseWriter, r *http.Request) {
 		ticker := r.URL.Query().Get("ticker")
 		priceStr := r.URL.Query().Get("price")
 		direction := r.URL.Query().Get("direction")
@@ -205,46 +216,45 @@ func main() {
 			return
 		}
 
-		apiKey := os.Getenv("TIINGO_API_KEY")
-		if apiKey == "" {
-			GenerateError(w, "Missing API key.", http.StatusInternalServerError)
-			return
-		}
-
-		client := &http.Client{}
-		url := fmt.Sprintf("https://api.tiingo.com/iex/%s?token=%s", ticker, apiKey)
-		resp, err := client.Get(url)
-
-		if err != nil {
-			netErr, ok := err.(net.Error)
-			if ok && netErr.Timeout() {
-				GenerateError(w, "Network timeout.", http.StatusGatewayTimeout)
+		// The upstream lookup (not the final valid/invalid verdict, which
+		// also depends on price/direction) is what's cached here, keyed
+		// only by ticker, to collapse bursts of alert checks for the same
+		// symbol.
+		cacheKey := "iex|" + ticker
+		var last providers.LastPrice
+		if cached, ok := dataCache.Get(cacheKey); ok {
+			metrics.CacheHitsTotal.WithLabelValues("/check-alert").Inc()
+			w.Header().Set("X-Cache", "HIT")
+			json.Unmarshal(cached, &last)
+		} else {
+			metrics.CacheMissesTotal.WithLabelValues("/check-alert").Inc()
+			w.Header().Set("X-Cache", "MISS")
+
+			last, err = provider.LatestPrice(r.Context(), ticker)
+			if errors.Is(err, providers.ErrNoData) {
+				errMsg := fmt.Sprintf("No price data found for ticker: %s", ticker)
+				log.Println("WARN:", errMsg)
+				GenerateError(w, errMsg, http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				log.Println("ERROR: provider request failed -", err)
+				GenerateError(w, "Error fetching price data.", http.StatusBadGateway)
 				return
 			}
 
-			// Other network-related error
-			GenerateError(w, "Network error.", http.StatusBadGateway)
-			return
-		}
-		defer resp.Body.Close()
-
-		body, _ := io.ReadAll(resp.Body)
-		var result []LastPrice
-		json.Unmarshal(body, &result)
-		if len(result) == 0 {
-			errMsg := fmt.Sprintf("No price data found for ticker: %s", ticker)
-			log.Println("WARN:", errMsg)
-			GenerateError(w, errMsg, http.StatusNotFound)
-			return
+			if body, err := json.Marshal(last); err == nil {
+				dataCache.Set(cacheKey, body, 3*time.Second)
+			}
 		}
 
 		// Use last price if it exists (i.e. market is open)
 		// Use Previous close otherwise (i.e. market is closed)
 		var currentPrice float64
-		if result[0].Last != nil {
-			currentPrice = *result[0].Last
+		if last.Last != nil {
+			currentPrice = *last.Last
 		} else {
-			currentPrice = result[0].PrevClose
+			currentPrice = last.PrevClose
 			log.Printf(`⚠️  Live price unavailable for %s — using previous close.`, ticker)
 		}
 
@@ -268,22 +278,231 @@ func main() {
 		})
 	})
 
+	// --------- Persistent Alerts ---------
+
+	// Alerts are persisted to SQLite and evaluated in the background by an
+	// Engine, independently of the one-shot /check-alert validation above.
+	alertDBPath := os.Getenv("ALERT_DB_PATH")
+	if alertDBPath == "" {
+		alertDBPath = "alerts.db"
+	}
+	alertStore, err := alerts.NewSQLiteStore(alertDBPath)
+	if err != nil {
+		log.Fatalf("⚠️  %v", err)
+	}
+
+	pollInterval := 10 * time.Second
+	if raw := os.Getenv("ALERT_POLL_INTERVAL_SECONDS"); raw != "" {
+		if secs, err := strconv.Atoi(raw); err == nil {
+			pollInterval = time.Duration(secs) * time.Second
+		}
+	}
+	workerPool := 8
+	if raw := os.Getenv("ALERT_WORKER_POOL_SIZE"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil {
+			workerPool = n
+		}
+	}
+
+	alertEngine := alerts.NewEngine(alertStore, provider, pollInterval, workerPool, os.Getenv("ALERT_WEBHOOK_SECRET"), conns)
+	conns.Add(1)
+	go func() {
+		defer conns.Done()
+		alertEngine.Run(rootCtx)
+	}()
+
+	// Define POST /alerts (create) and GET /alerts (list)
+	registerRoute("/alerts", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var req struct {
+				Ticker      string  `json:"ticker"`
+				Price       float64 `json:"price"`
+				Direction   string  `json:"direction"`
+				CallbackURL string  `json:"callbackURL"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				GenerateError(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			if req.Ticker == "" || req.CallbackURL == "" || (req.Direction != "above" && req.Direction != "below") {
+				GenerateError(w, "Missing or invalid required fields", http.StatusBadRequest)
+				return
+			}
+			if err := alerts.ValidateCallbackURL(req.CallbackURL); err != nil {
+				GenerateError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			created, err := alertStore.Create(alerts.Alert{
+				Ticker:      req.Ticker,
+				Price:       req.Price,
+				Direction:   req.Direction,
+				CallbackURL: req.CallbackURL,
+			})
+			if err != nil {
+				log.Println("ERROR: failed to create alert -", err)
+				GenerateError(w, "Failed to create alert", http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(created)
+
+		case http.MethodGet:
+			list, err := alertStore.List()
+			if err != nil {
+				log.Println("ERROR: failed to list alerts -", err)
+				GenerateError(w, "Failed to list alerts", http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(list)
+
+		default:
+			GenerateError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Define DELETE /alerts/{id}
+	registerRoute("/alerts/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			GenerateError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/alerts/")
+		if id == "" {
+			GenerateError(w, "Missing alert id", http.StatusBadRequest)
+			return
+		}
+
+		if err := alertStore.Delete(id); err != nil {
+			if errors.Is(err, alerts.ErrNotFound) {
+				GenerateError(w, "Alert not found", http.StatusNotFound)
+				return
+			}
+			log.Println("ERROR: failed to delete alert -", err)
+			GenerateError(w, "Failed to delete alert", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// --------- Metrics ---------
+
+	// Define /metrics route. Left off the standard middleware stack so
+	// scraping it doesn't pollute its own request counters.
+	http.Handle("/metrics", metrics.Handler())
+
 	// --------- Start Server ---------
 
-	// Start server
+	// TLS is off by default (TLS_MODE=off); set TLS_MODE=manual or
+	// TLS_MODE=autocert to terminate TLS here instead of behind an
+	// external reverse proxy.
+	tlsCfg, err := tlsconfig.FromEnv()
+	if err != nil {
+		log.Fatalf("⚠️  %v", err)
+	}
+
 	port := ":8080"
-	log.Printf("🌐 Server starting on http://localhost%s", port)
+	scheme := "http"
+	if tlsCfg.Mode != tlsconfig.ModeOff {
+		port = ":443"
+		scheme = "https"
+	}
+
+	log.Printf("🌐 Server starting on %s://localhost%s", scheme, port)
 	log.Printf("📊 Available endpoints:")
-	log.Printf("   GET  http://localhost%s/health", port)
-	log.Printf("   GET  http://localhost%s/stocks?ticker=<symbol>&startDate=<date>&interval=<freq>", port)
-	log.Printf("   GET  http://localhost%s/check-alert?ticker=<symbol>&price=<price>&direction=<above|below>", port)
+	log.Printf("   GET  %s://localhost%s/health", scheme, port)
+	log.Printf("   GET  %s://localhost%s/stocks?ticker=<symbol>&startDate=<date>&interval=<freq>", scheme, port)
+	log.Printf("   GET  %s://localhost%s/check-alert?ticker=<symbol>&price=<price>&direction=<above|below>", scheme, port)
+	log.Printf("   POST %s://localhost%s/alerts", scheme, port)
+	log.Printf("   GET  %s://localhost%s/alerts", scheme, port)
+	log.Printf("   DEL  %s://localhost%s/alerts/<id>", scheme, port)
+	log.Printf("   WS   %s://localhost%s/stream", scheme, port)
+	log.Printf("   GET  %s://localhost%s/metrics", scheme, port)
 
 	// Start HTTP server
 	server := &http.Server{
 		Addr:         port,
 		ReadTimeout:  6 * time.Second,
 		WriteTimeout: 6 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// challengeServer, when non-nil, answers the ACME HTTP-01 challenge on
+	// :80 and redirects everything else to HTTPS. Only autocert mode needs it.
+	var challengeServer *http.Server
+	if tlsCfg.Mode == tlsconfig.ModeAutocert {
+		m := tlsCfg.Manager()
+		server.TLSConfig = m.TLSConfig()
+		challengeServer = tlsconfig.ChallengeServer(m)
+		go func() {
+			if err := challengeServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("⚠️  ACME challenge/redirect server error: %v", err)
+			}
+		}()
+	}
+
+	go func() {
+		var err error
+		switch tlsCfg.Mode {
+		case tlsconfig.ModeManual:
+			err = server.ListenAndServeTLS(tlsCfg.CertFile, tlsCfg.KeyFile)
+		case tlsconfig.ModeAutocert:
+			err = server.ListenAndServeTLS("", "")
+		default:
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatalf("⚠️  server error: %v", err)
+		}
+	}()
+
+	// Block until a SIGINT/SIGTERM arrives, then drain.
+	<-rootCtx.Done()
+	shutdown(server, challengeServer, conns)
+}
+
+// shutdown drains in-flight requests and long-lived handlers (WebSocket
+// clients, the alert engine) within SHUTDOWN_GRACE_SECONDS (default 30s),
+// then hammers anything still outstanding closed. challengeServer is nil
+// unless TLS_MODE=autocert started one.
+func shutdown(server, challengeServer *http.Server, conns *lifecycle.Group) {
+	grace := 30 * time.Second
+	if raw := os.Getenv("SHUTDOWN_GRACE_SECONDS"); raw != "" {
+		if secs, err := strconv.Atoi(raw); err == nil {
+			grace = time.Duration(secs) * time.Second
+		}
+	}
+
+	log.Printf("🛑 shutdown signal received, draining for up to %s (%d long-lived connections outstanding)", grace, conns.Active())
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	outstanding := conns.Active()
+
+	// server.Shutdown stops accepting new connections and waits for
+	// in-flight HTTP requests (not hijacked WebSocket connections, which
+	// lifecycle.Group tracks separately) to finish.
+	shutdownErr := server.Shutdown(drainCtx)
+	if challengeServer != nil {
+		challengeServer.Shutdown(drainCtx)
+	}
+	connsErr := conns.Wait(drainCtx)
+
+	if shutdownErr != nil || connsErr != nil {
+		// Hammer time: force-close whatever is still open rather than wait
+		// any longer.
+		server.Close()
+		log.Printf("⏱️  hammer time: %d/%d long-lived connections drained cleanly, %d force-closed after %s grace period",
+			outstanding-conns.Active(), outstanding, conns.Active(), grace)
+		return
 	}
 
-	log.Fatal(server.ListenAndServe())
+	log.Printf("✅ drained all %d long-lived connections cleanly", outstanding)
 }
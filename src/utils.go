@@ -0,0 +1,16 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GenerateError writes a JSON error body of the form {"detail": message}
+// with statusCode.
+func GenerateError(w http.ResponseWriter, message string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"detail": message,
+	})
+}